@@ -0,0 +1,299 @@
+package bnet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TimeLeft is Blizzard's coarse bucket for how long an auction has left to
+// run, since the API never returns an exact timestamp.
+type TimeLeft string
+
+const (
+	TimeLeftVeryLong  TimeLeft = "VERY_LONG"
+	TimeLeftLong      TimeLeft = "LONG"
+	TimeLeftMedium    TimeLeft = "MEDIUM"
+	TimeLeftShort     TimeLeft = "SHORT"
+	TimeLeftVeryShort TimeLeft = "VERY_SHORT"
+)
+
+// Modifier is a per-auction item modifier, e.g. a socket or tertiary stat
+// roll, identified by Blizzard's numeric modifier type.
+type Modifier struct {
+	Type  int `json:"type"`
+	Value int `json:"value"`
+}
+
+// ItemBonus identifies a bonus list applied to an auctioned item (item
+// level upgrades, sockets, and the like). The auctions API returns these
+// as opaque integer IDs.
+type ItemBonus int
+
+// Item is the item side of an Auction: the base item plus whatever
+// bonuses/modifiers make this particular listing different from another
+// listing of the same item ID.
+type Item struct {
+	ID         int         `json:"id"`
+	Context    int         `json:"context,omitempty"`
+	BonusLists []ItemBonus `json:"bonus_lists,omitempty"`
+	Modifiers  []Modifier  `json:"modifiers,omitempty"`
+}
+
+// Auction is a single listing, either a commodity (stackable, no bid) or
+// an item auction (unique, bid/buyout). Money fields are int64 copper,
+// matching how Blizzard's API represents currency.
+type Auction struct {
+	ID              int64    `json:"id"`
+	Item            Item     `json:"item"`
+	BidCopper       int64    `json:"bid,omitempty"`
+	BuyoutCopper    int64    `json:"buyout,omitempty"`
+	UnitPriceCopper int64    `json:"unit_price,omitempty"`
+	Quantity        int      `json:"quantity"`
+	TimeLeft        TimeLeft `json:"time_left"`
+}
+
+// price is the value a Watcher compares between polls to detect a
+// price-changed listing: a commodity's unit price, or an item auction's
+// buyout, falling back to its bid if there is no buyout.
+func (a Auction) price() int64 {
+	switch {
+	case a.UnitPriceCopper != 0:
+		return a.UnitPriceCopper
+	case a.BuyoutCopper != 0:
+		return a.BuyoutCopper
+	default:
+		return a.BidCopper
+	}
+}
+
+type auctionsResponse struct {
+	Auctions []Auction `json:"auctions"`
+}
+
+// ItemMediaAsset is one image/icon variant returned for an item, e.g.
+// "icon".
+type ItemMediaAsset struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ItemMedia is the media (icon/render) assets registered for an item ID.
+type ItemMedia struct {
+	ID     int              `json:"id"`
+	Assets []ItemMediaAsset `json:"assets"`
+}
+
+// Auctions is a typed wrapper around BNet's auction house endpoints. It
+// exists so callers don't have to re-implement the request shapes and
+// response unmarshalling auc-bnet was built to serve.
+type Auctions struct {
+	bnet *BNet
+}
+
+// NewAuctions wraps b with the auction house endpoints.
+func NewAuctions(b *BNet) *Auctions {
+	return &Auctions{bnet: b}
+}
+
+// locale returns the locale Auctions should request for region: the BNet's
+// configured Locale if it has one, otherwise region's default. Without
+// this, every call would be silently pinned to en_US regardless of what
+// the caller (or region) actually wants.
+func (a *Auctions) locale(region Region) Locale {
+	if l := a.bnet.Locale(); l != "" {
+		return l
+	}
+
+	return region.DefaultLocale()
+}
+
+// GetCommodities returns every commodity auction (stackable items like
+// reagents, which aren't scoped to a connected realm) for region. Repeated
+// polls only pay for new data when b was constructed with a Cache.
+func (a *Auctions) GetCommodities(ctx context.Context, region Region) ([]Auction, error) {
+	body, _, err := a.bnet.GetCtx(ctx, string(region),
+		fmt.Sprintf("data/wow/auctions/commodities?namespace=dynamic-%s&locale=%s", region, a.locale(region)))
+	if err != nil {
+		return nil, err
+	}
+
+	var resp auctionsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshal commodities response: %w", err)
+	}
+
+	return resp.Auctions, nil
+}
+
+// GetAuctions returns every item auction (unique, bid/buyout) for the
+// given connected realm.
+func (a *Auctions) GetAuctions(ctx context.Context, region Region, connectedRealmID int) ([]Auction, error) {
+	body, _, err := a.bnet.GetCtx(ctx, string(region),
+		fmt.Sprintf("data/wow/connected-realm/%d/auctions?namespace=dynamic-%s&locale=%s",
+			connectedRealmID, region, a.locale(region)))
+	if err != nil {
+		return nil, err
+	}
+
+	var resp auctionsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("unmarshal auctions response: %w", err)
+	}
+
+	return resp.Auctions, nil
+}
+
+// GetItemMedia returns the icon/render assets registered for itemID, so a
+// caller rendering a listing doesn't need a separate item database.
+func (a *Auctions) GetItemMedia(ctx context.Context, region Region, itemID int) (*ItemMedia, error) {
+	body, _, err := a.bnet.GetCtx(ctx, string(region),
+		fmt.Sprintf("data/wow/media/item/%d?namespace=static-%s&locale=%s", itemID, region, a.locale(region)))
+	if err != nil {
+		return nil, err
+	}
+
+	var media ItemMedia
+	if err := json.Unmarshal(body, &media); err != nil {
+		return nil, fmt.Errorf("unmarshal item media response: %w", err)
+	}
+
+	return &media, nil
+}
+
+// DiffKind categorizes how a listing changed between two Watcher polls.
+type DiffKind string
+
+const (
+	DiffNew          DiffKind = "new"
+	DiffRemoved      DiffKind = "removed"
+	DiffPriceChanged DiffKind = "price_changed"
+)
+
+// Diff describes one listing change observed by a Watcher.
+type Diff struct {
+	Region           Region
+	ConnectedRealmID int
+	Kind             DiffKind
+	Auction          Auction
+
+	// PreviousPrice is only set when Kind is DiffPriceChanged.
+	PreviousPrice int64
+}
+
+// Watcher polls a fixed set of connected realms on an interval and emits
+// a Diff for every new, removed, or price-changed listing it observes —
+// the primary use case auc-bnet exists to serve.
+type Watcher struct {
+	auctions *Auctions
+	region   Region
+	realms   []int
+	interval time.Duration
+
+	mu   sync.Mutex
+	seen map[int]map[int64]Auction
+}
+
+// NewWatcher builds a Watcher over the given connected realms in region,
+// polling every interval.
+func NewWatcher(auctions *Auctions, region Region, connectedRealmIDs []int, interval time.Duration) *Watcher {
+	return &Watcher{
+		auctions: auctions,
+		region:   region,
+		realms:   connectedRealmIDs,
+		interval: interval,
+		seen:     make(map[int]map[int64]Auction),
+	}
+}
+
+// Watch polls every configured connected realm, once immediately and then
+// every interval, sending a Diff for each change to the returned channel.
+// The channel is closed once ctx is done.
+func (w *Watcher) Watch(ctx context.Context) <-chan Diff {
+	out := make(chan Diff)
+
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		w.pollAll(ctx, out)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.pollAll(ctx, out)
+			}
+		}
+	}()
+
+	return out
+}
+
+func (w *Watcher) pollAll(ctx context.Context, out chan<- Diff) {
+	for _, realmID := range w.realms {
+		if ctx.Err() != nil {
+			return
+		}
+
+		w.poll(ctx, realmID, out)
+	}
+}
+
+// poll fetches the current listings for connRealmID and diffs them
+// against the last poll, sending one Diff per change.
+func (w *Watcher) poll(ctx context.Context, connRealmID int, out chan<- Diff) {
+	current, err := w.auctions.GetAuctions(ctx, w.region, connRealmID)
+	if err != nil {
+		w.auctions.bnet.log.Err(err).
+			Str("region", string(w.region)).
+			Int("connectedRealmID", connRealmID).
+			Msg("Watcher poll failed, skipping this cycle")
+
+		return
+	}
+
+	currentByID := make(map[int64]Auction, len(current))
+	for _, auc := range current {
+		currentByID[auc.ID] = auc
+	}
+
+	w.mu.Lock()
+	previous := w.seen[connRealmID]
+
+	var diffs []Diff
+	for id, auc := range currentByID {
+		prev, existed := previous[id]
+		switch {
+		case !existed:
+			diffs = append(diffs, Diff{Region: w.region, ConnectedRealmID: connRealmID, Kind: DiffNew, Auction: auc})
+		case prev.price() != auc.price():
+			diffs = append(diffs, Diff{
+				Region: w.region, ConnectedRealmID: connRealmID, Kind: DiffPriceChanged,
+				Auction: auc, PreviousPrice: prev.price(),
+			})
+		}
+	}
+
+	for id, auc := range previous {
+		if _, stillThere := currentByID[id]; !stillThere {
+			diffs = append(diffs, Diff{Region: w.region, ConnectedRealmID: connRealmID, Kind: DiffRemoved, Auction: auc})
+		}
+	}
+
+	w.seen[connRealmID] = currentByID
+	w.mu.Unlock()
+
+	for _, d := range diffs {
+		select {
+		case out <- d:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
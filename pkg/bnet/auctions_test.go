@@ -0,0 +1,100 @@
+package bnet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubTokenHandler hands back a fixed token without making a network call,
+// so tests don't need to stand up a fake OAuth endpoint too.
+type stubTokenHandler struct{}
+
+func (stubTokenHandler) Token(Challenge) (Token, error) {
+	return Token{AccessToken: "test-token"}, nil
+}
+
+func newTestAuctions(t *testing.T, responses ...auctionsResponse) *Auctions {
+	t.Helper()
+
+	var call int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := atomic.AddInt32(&call, 1) - 1
+		if int(i) >= len(responses) {
+			i = int32(len(responses) - 1)
+		}
+
+		if err := json.NewEncoder(w).Encode(responses[i]); err != nil {
+			t.Fatalf("encode response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	b, err := New("id", "secret", "https://{region}.unittest.com", server.URL+"/{region}", &Options{
+		TokenHandler: stubTokenHandler{},
+	})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	return NewAuctions(b)
+}
+
+func Test_Watcher_poll(t *testing.T) {
+	first := auctionsResponse{Auctions: []Auction{
+		{ID: 1, BuyoutCopper: 1000},
+		{ID: 2, BuyoutCopper: 2000},
+	}}
+	second := auctionsResponse{Auctions: []Auction{
+		{ID: 1, BuyoutCopper: 1500}, // price changed
+		{ID: 3, BuyoutCopper: 3000}, // new
+		// ID 2 removed
+	}}
+
+	auctions := newTestAuctions(t, first, second)
+	w := NewWatcher(auctions, RegionUS, []int{1}, time.Hour)
+
+	out := make(chan Diff, 10)
+	w.poll(context.Background(), 1, out)
+	if len(out) != 2 {
+		t.Fatalf("first poll should report every listing as new, got %d diffs", len(out))
+	}
+
+	// drain the baseline diffs so only the second poll's diffs remain below
+	<-out
+	<-out
+
+	w.poll(context.Background(), 1, out)
+	close(out)
+
+	got := map[DiffKind]int{}
+	for d := range out {
+		got[d.Kind]++
+
+		switch d.Kind {
+		case DiffNew:
+			if d.Auction.ID != 3 {
+				t.Errorf("DiffNew auction ID = %d, want 3", d.Auction.ID)
+			}
+		case DiffRemoved:
+			if d.Auction.ID != 2 {
+				t.Errorf("DiffRemoved auction ID = %d, want 2", d.Auction.ID)
+			}
+		case DiffPriceChanged:
+			if d.Auction.ID != 1 || d.PreviousPrice != 1000 {
+				t.Errorf("DiffPriceChanged = %+v, want ID 1 with PreviousPrice 1000", d)
+			}
+		}
+	}
+
+	want := map[DiffKind]int{DiffNew: 1, DiffRemoved: 1, DiffPriceChanged: 1}
+	for kind, count := range want {
+		if got[kind] != count {
+			t.Errorf("got %d %s diffs, want %d", got[kind], kind, count)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 
 	"golang.org/x/text/transform"
@@ -20,7 +21,19 @@ import (
 
 // HTTP contains mockable bnet http calls
 type HTTP interface {
+	// Get is a deprecated alias for GetCtx(context.Background(), ...).
 	Get(region, endpoint string) ([]byte, http.Header, error)
+
+	// GetCtx is like Get, but ctx governs rate-limit waits, retry
+	// backoff, and the underlying request, so a caller can cancel a slow
+	// call instead of waiting it out.
+	GetCtx(ctx context.Context, region, endpoint string) ([]byte, http.Header, error)
+
+	// Concurrency reports how many requests a caller may safely have in
+	// flight at once, so fan-out callers like GetRealmList cooperate with
+	// the implementation's own rate limiting instead of guessing a
+	// thread count.
+	Concurrency() int
 }
 
 // AllRealmCollection maps a realm slug to a blizzard realm ID
@@ -40,15 +53,39 @@ type Realms struct {
 	Region          string
 	ConnectedRealms ConnectedRealmCollection
 	AllRealms       AllRealmCollection
-	crRealm         map[string]int
+	// Names maps a realm slug to its localized display name, keyed by
+	// Locale, so callers can look up the name a Region+Locale pair would
+	// show a player instead of hard-coding en_US.
+	Names   map[string]map[Locale]string
+	crRealm map[string]int
 }
 
-// GetRealmList calculates valid realm ids for a given region
-// This methods makes many API calls to populate the full list of connected realms
+// GetRealmList is a deprecated alias for
+// GetRealmListCtx(context.Background(), ...) using region's default
+// locale. It will be removed in a future release; callers should migrate
+// to GetRealmListCtx.
 func GetRealmList(h HTTP, region string) (*Realms, error) {
+	r := Region(region)
+
+	return GetRealmListCtx(context.Background(), h, r, r.DefaultLocale())
+}
+
+// GetRealmListCtx calculates valid realm ids for a given region and
+// populates Realms.Names with each realm's display name in locale.
+// This method makes many API calls to populate the full list of connected
+// realms; ctx is propagated (via an errgroup-derived context) into every
+// worker's call, so a single failed scrape cancels the rest instead of
+// letting them run to completion.
+func GetRealmListCtx(ctx context.Context, h HTTP, region Region, locale Locale) (*Realms, error) {
+	if locale == "" {
+		locale = region.DefaultLocale()
+	}
+
+	regionStr := string(region)
+
 	// get all realms
-	resp, _, err := h.Get(region, fmt.Sprintf("data/wow/realm/index?locale=en_US&namespace=dynamic-%s",
-		region))
+	resp, _, err := h.GetCtx(ctx, regionStr, fmt.Sprintf("data/wow/realm/index?locale=%s&namespace=dynamic-%s",
+		locale, regionStr))
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +94,7 @@ func GetRealmList(h HTTP, region string) (*Realms, error) {
 		Realms []struct {
 			ID   int
 			Slug string
+			Name string
 		}
 	}
 
@@ -66,8 +104,8 @@ func GetRealmList(h HTTP, region string) (*Realms, error) {
 	}
 
 	// get all connected realms
-	resp, _, err = h.Get(region, fmt.Sprintf("data/wow/connected-realm/index?locale=en_US&namespace=dynamic-%s",
-		region))
+	resp, _, err = h.GetCtx(ctx, regionStr, fmt.Sprintf("data/wow/connected-realm/index?locale=%s&namespace=dynamic-%s",
+		locale, regionStr))
 	if err != nil {
 		return nil, err
 	}
@@ -108,15 +146,14 @@ func GetRealmList(h HTTP, region string) (*Realms, error) {
 	}
 	close(crCh)
 
-	ctx := context.Background()
-	eg, ctx := errgroup.WithContext(ctx)
-	threads := 5
+	eg, egCtx := errgroup.WithContext(ctx)
+	threads := h.Concurrency()
 	for i := 0; i < threads; i++ {
 		eg.Go(func() error {
 			for crID := range crCh {
 				c := crID
 				// scrape all realms attached to this connected realm, mutate crc
-				realms, err := rs.scrapeConnRealm(h, region, c, crc)
+				realms, err := rs.scrapeConnRealm(egCtx, h, regionStr, locale, c, crc)
 				if err != nil {
 					return err
 				}
@@ -127,8 +164,6 @@ func GetRealmList(h HTTP, region string) (*Realms, error) {
 					crRealm[r] = c
 				}
 				rs.lock.Unlock()
-
-				return nil
 			}
 
 			return nil
@@ -139,8 +174,10 @@ func GetRealmList(h HTTP, region string) (*Realms, error) {
 		return nil, err
 	}
 
+	names := make(map[string]map[Locale]string, len(rlr.Realms))
 	for _, r := range rlr.Realms {
 		ar[r.Slug] = r.ID
+		names[r.Slug] = map[Locale]string{locale: r.Name}
 		crcCheck[r.Slug]--
 		if crcCheck[r.Slug] == 0 {
 			delete(crcCheck, r.Slug)
@@ -154,7 +191,8 @@ func GetRealmList(h HTTP, region string) (*Realms, error) {
 	r := &Realms{
 		ConnectedRealms: crc,
 		AllRealms:       ar,
-		Region:          region,
+		Names:           names,
+		Region:          regionStr,
 		crRealm:         crRealm,
 	}
 
@@ -174,9 +212,17 @@ func init() {
 	}
 }
 
-// ConnectedRealmID retrieves a connected realm given the region and realm slug.
-// Note maps are always passed by reference, so a pointer receiver here doesn't matter!
+// ConnectedRealmID is a deprecated alias for
+// ConnectedRealmIDCtx(context.Background(), ...).
 func (r Realms) ConnectedRealmID(h HTTP, realmSlug string) (int, error) {
+	return r.ConnectedRealmIDCtx(context.Background(), h, realmSlug)
+}
+
+// ConnectedRealmIDCtx retrieves a connected realm given the region and
+// realm slug. ctx is accepted for API consistency with the rest of the
+// package's Ctx variants; the lookup itself never touches the network.
+// Note maps are always passed by reference, so a pointer receiver here doesn't matter!
+func (r Realms) ConnectedRealmIDCtx(_ context.Context, h HTTP, realmSlug string) (int, error) {
 	id, ok := r.crRealm[realmSlug]
 	if !ok {
 		return -1, errors.New("realm not found in region")
@@ -185,9 +231,61 @@ func (r Realms) ConnectedRealmID(h HTTP, realmSlug string) (int, error) {
 	return id, nil
 }
 
-func (r *realmScanner) scrapeConnRealm(h HTTP, region string, connRealmId int, c ConnectedRealmCollection) ([]string, error) {
-	resp, _, err := h.Get(region, fmt.Sprintf("/data/wow/connected-realm/%d?namespace=dynamic-%s&locale=en_US",
-		connRealmId, region))
+// realmsCacheKey is the cache key a Realms snapshot is stored under.
+func realmsCacheKey(region string) string {
+	return fmt.Sprintf("%s/realm-snapshot", region)
+}
+
+// SaveTo persists r to cache with the given ttl, so a later LoadRealms
+// call within that window can skip GetRealmList's connected-realm crawl.
+// A zero ttl means the snapshot is considered stale immediately, so
+// LoadRealms never returns it.
+func (r *Realms) SaveTo(cache Cache, ttl time.Duration) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	var meta CacheMeta
+	if ttl > 0 {
+		meta.Expiry = time.Now().Add(ttl)
+	}
+
+	return cache.Put(realmsCacheKey(r.Region), body, meta)
+}
+
+// LoadRealms returns the Realms snapshot previously saved for region with
+// SaveTo, or ErrCacheMiss if there is none or it has gone stale. Callers
+// should fall back to GetRealmList on a miss, and can force one with
+// cache.Invalidate(region) after Blizzard's weekly reset.
+func LoadRealms(cache Cache, region string) (*Realms, error) {
+	body, meta, err := cache.Get(realmsCacheKey(region))
+	if err != nil {
+		return nil, err
+	}
+
+	if !meta.fresh() {
+		return nil, ErrCacheMiss
+	}
+
+	var realms Realms
+	if err := json.Unmarshal(body, &realms); err != nil {
+		return nil, err
+	}
+
+	realms.crRealm = make(map[string]int, len(realms.AllRealms))
+	for connID, slugs := range realms.ConnectedRealms {
+		for _, slug := range slugs {
+			realms.crRealm[slug] = connID
+		}
+	}
+
+	return &realms, nil
+}
+
+func (r *realmScanner) scrapeConnRealm(ctx context.Context, h HTTP, region string, locale Locale, connRealmId int, c ConnectedRealmCollection) ([]string, error) {
+	resp, _, err := h.GetCtx(ctx, region, fmt.Sprintf("/data/wow/connected-realm/%d?namespace=dynamic-%s&locale=%s",
+		connRealmId, region, locale))
 	if err != nil {
 		return nil, err
 	}
@@ -245,9 +343,9 @@ func RealmSlug(realm string) string {
 	return result
 }
 
-// IsValidRegion accepts region strings "us" or "eu"
+// IsValidRegion reports whether region is one of Battle.net's known
+// region codes ("us", "eu", "kr", "tw", "cn"). It is a thin wrapper
+// around Region.IsValid for callers that only have a string on hand.
 func IsValidRegion(region string) bool {
-	i := strings.Index("useu", region)
-
-	return i == 0 || i == 2
+	return Region(region).IsValid()
 }
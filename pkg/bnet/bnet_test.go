@@ -1,7 +1,9 @@
 package bnet
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"reflect"
 	"testing"
@@ -18,6 +20,10 @@ type mockHTTP struct {
 }
 
 func (m *mockHTTP) Get(region, endpoint string) ([]byte, http.Header, error) {
+	return m.GetCtx(context.Background(), region, endpoint)
+}
+
+func (m *mockHTTP) GetCtx(_ context.Context, region, endpoint string) ([]byte, http.Header, error) {
 	respData, ok := m.getMap[endpoint]
 	if !ok {
 		panic("unexpected endpoint")
@@ -31,6 +37,12 @@ func (m *mockHTTP) Get(region, endpoint string) ([]byte, http.Header, error) {
 	return b, respData.headers, respData.err
 }
 
+// Concurrency matches the old hard-coded thread count, since the tests
+// below don't exercise BNet's real rate limiter.
+func (m *mockHTTP) Concurrency() int {
+	return 5
+}
+
 type mockRealmListResp struct {
 	Realms []struct {
 		ID   int
@@ -134,6 +146,13 @@ func TestGetRealmList(t *testing.T) {
 			1: []string{"realm1-main", "realm2", "realm5"},
 			3: []string{"realm3-main", "realm4"},
 		},
+		Names: map[string]map[Locale]string{
+			"realm1-main": {LocaleEnUS: ""},
+			"realm2":      {LocaleEnUS: ""},
+			"realm3-main": {LocaleEnUS: ""},
+			"realm4":      {LocaleEnUS: ""},
+			"realm5":      {LocaleEnUS: ""},
+		},
 	}
 
 	if !reflect.DeepEqual(realms, expected) {
@@ -141,3 +160,71 @@ func TestGetRealmList(t *testing.T) {
 		return
 	}
 }
+
+// TestGetRealmList_moreConnectedRealmsThanConcurrency is a regression test
+// for c1f95e7: GetRealmListCtx's worker goroutines used to return after
+// scraping a single connected realm instead of draining the work channel,
+// so any region with more connected realms than Concurrency() (every real
+// US/EU region) failed with "realms not completely scraped". mockHTTP's
+// Concurrency() is 5, so this uses 8 connected realms to exceed it.
+func TestGetRealmList_moreConnectedRealmsThanConcurrency(t *testing.T) {
+	const numConnRealms = 8
+
+	var realms []struct {
+		ID   int
+		Slug string
+	}
+	var connRealms []struct{ Href string }
+	getMap := map[string]getResp{}
+
+	expectedAllRealms := AllRealmCollection{}
+	expectedCrRealm := map[string]int{}
+	expectedConnRealms := ConnectedRealmCollection{}
+	expectedNames := map[string]map[Locale]string{}
+
+	for i := 1; i <= numConnRealms; i++ {
+		slug := fmt.Sprintf("realm%d", i)
+		realms = append(realms, struct {
+			ID   int
+			Slug string
+		}{ID: i, Slug: slug})
+		connRealms = append(connRealms, struct{ Href string }{
+			Href: fmt.Sprintf("https://unittest.com/data/wow/connected-realm/%d?namespace=dynamic-us", i),
+		})
+
+		getMap[fmt.Sprintf("/data/wow/connected-realm/%d?namespace=dynamic-us&locale=en_US", i)] = getResp{
+			data: mockSingleConnRealmResp{Realms: []crEntry{{i, slug}}},
+		}
+
+		expectedAllRealms[slug] = i
+		expectedCrRealm[slug] = i
+		expectedConnRealms[i] = []string{slug}
+		expectedNames[slug] = map[Locale]string{LocaleEnUS: ""}
+	}
+
+	getMap["data/wow/realm/index?locale=en_US&namespace=dynamic-us"] = getResp{
+		data: mockRealmListResp{Realms: realms},
+	}
+	getMap["data/wow/connected-realm/index?locale=en_US&namespace=dynamic-us"] = getResp{
+		data: mockConnectedRealmsResponse{ConnectedRealms: connRealms},
+	}
+
+	m := &mockHTTP{getMap: getMap}
+
+	got, err := GetRealmList(m, "us")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	expected := &Realms{
+		Region:          "us",
+		crRealm:         expectedCrRealm,
+		AllRealms:       expectedAllRealms,
+		ConnectedRealms: expectedConnRealms,
+		Names:           expectedNames,
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Error("results not equal")
+	}
+}
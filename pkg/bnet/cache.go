@@ -0,0 +1,189 @@
+package bnet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCacheMiss is returned by Cache.Get when key has no entry.
+var ErrCacheMiss = errors.New("bnet: cache miss")
+
+// CacheMeta carries the conditional-request metadata associated with a
+// cached response body, plus an optional local TTL.
+type CacheMeta struct {
+	LastModified string
+	ETag         string
+
+	// Expiry is when this entry should be treated as stale without
+	// asking the server. Zero means there is no local TTL, so the entry
+	// is always revalidated with If-Modified-Since/If-None-Match.
+	Expiry time.Time
+}
+
+// fresh reports whether m's TTL has not yet elapsed.
+func (m CacheMeta) fresh() bool {
+	return !m.Expiry.IsZero() && time.Now().Before(m.Expiry)
+}
+
+// Cache stores response bodies and their conditional-request metadata,
+// keyed by region+endpoint. BNet.Get uses it to send
+// If-Modified-Since/If-None-Match and to serve a 304 from the cached
+// copy instead of the network.
+type Cache interface {
+	Get(key string) ([]byte, CacheMeta, error)
+	Put(key string, body []byte, meta CacheMeta) error
+
+	// Invalidate removes every entry whose key has the given prefix, so
+	// callers can force a refresh of a region after Blizzard's weekly
+	// reset.
+	Invalidate(keyPrefix string) error
+}
+
+// cacheKey builds the key BNet.Get addresses the cache with, namespaced
+// by region so the same endpoint in two regions doesn't collide.
+func cacheKey(region, endpoint string) string {
+	return region + "/" + endpoint
+}
+
+type cacheEntry struct {
+	body []byte
+	meta CacheMeta
+}
+
+// MemoryCache is an in-process Cache backed by a map. It does not
+// persist across restarts.
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]cacheEntry
+}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]cacheEntry)}
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, CacheMeta, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, CacheMeta{}, ErrCacheMiss
+	}
+
+	return e.body, e.meta, nil
+}
+
+func (c *MemoryCache) Put(key string, body []byte, meta CacheMeta) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry{body: body, meta: meta}
+
+	return nil
+}
+
+func (c *MemoryCache) Invalidate(keyPrefix string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k := range c.entries {
+		if strings.HasPrefix(k, keyPrefix) {
+			delete(c.entries, k)
+		}
+	}
+
+	return nil
+}
+
+// FileCache is a Cache backed by one JSON file per entry under Dir,
+// similar in spirit to openbmclapi's on-disk storage manager. Keys are
+// hashed into filenames since endpoints contain characters ('/', '?')
+// that aren't safe to use as a path directly.
+type FileCache struct {
+	Dir string
+}
+
+// NewFileCache returns a FileCache that stores its entries under dir,
+// creating it on first Put if necessary.
+func NewFileCache(dir string) *FileCache {
+	return &FileCache{Dir: dir}
+}
+
+type fileCacheEntry struct {
+	Key  string    `json:"key"`
+	Body []byte    `json:"body"`
+	Meta CacheMeta `json:"meta"`
+}
+
+func (c *FileCache) pathFor(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *FileCache) Get(key string) ([]byte, CacheMeta, error) {
+	data, err := ioutil.ReadFile(c.pathFor(key))
+	if os.IsNotExist(err) {
+		return nil, CacheMeta{}, ErrCacheMiss
+	} else if err != nil {
+		return nil, CacheMeta{}, err
+	}
+
+	var entry fileCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, CacheMeta{}, err
+	}
+
+	return entry.Body, entry.Meta, nil
+}
+
+func (c *FileCache) Put(key string, body []byte, meta CacheMeta) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(fileCacheEntry{Key: key, Body: body, Meta: meta})
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.pathFor(key), data, 0o644)
+}
+
+func (c *FileCache) Invalidate(keyPrefix string) error {
+	entries, err := ioutil.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, fi := range entries {
+		p := filepath.Join(c.Dir, fi.Name())
+
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			continue
+		}
+
+		var entry fileCacheEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			continue
+		}
+
+		if strings.HasPrefix(entry.Key, keyPrefix) {
+			os.Remove(p)
+		}
+	}
+
+	return nil
+}
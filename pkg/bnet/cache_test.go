@@ -0,0 +1,132 @@
+package bnet
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_MemoryCache_roundTrip(t *testing.T) {
+	c := NewMemoryCache()
+
+	if _, _, err := c.Get("us/realm"); err != ErrCacheMiss {
+		t.Errorf("Get() on empty cache err = %v, want ErrCacheMiss", err)
+	}
+
+	meta := CacheMeta{ETag: `"abc"`, Expiry: time.Now().Add(time.Hour)}
+	if err := c.Put("us/realm", []byte("body"), meta); err != nil {
+		t.Fatalf("Put() unexpected error: %v", err)
+	}
+
+	body, gotMeta, err := c.Get("us/realm")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+
+	if string(body) != "body" {
+		t.Errorf("Get() body = %q, want %q", body, "body")
+	}
+
+	if !gotMeta.fresh() {
+		t.Error("gotMeta.fresh() = false, want true within Expiry")
+	}
+
+	if gotMeta.ETag != meta.ETag {
+		t.Errorf("gotMeta.ETag = %q, want %q", gotMeta.ETag, meta.ETag)
+	}
+}
+
+func Test_MemoryCache_expiredEntryIsNotFresh(t *testing.T) {
+	c := NewMemoryCache()
+
+	meta := CacheMeta{Expiry: time.Now().Add(-time.Minute)}
+	if err := c.Put("us/realm", []byte("body"), meta); err != nil {
+		t.Fatalf("Put() unexpected error: %v", err)
+	}
+
+	_, gotMeta, err := c.Get("us/realm")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+
+	if gotMeta.fresh() {
+		t.Error("gotMeta.fresh() = true, want false after Expiry has passed")
+	}
+}
+
+func Test_MemoryCache_invalidate(t *testing.T) {
+	c := NewMemoryCache()
+
+	if err := c.Put("us/realm", []byte("body"), CacheMeta{}); err != nil {
+		t.Fatalf("Put() unexpected error: %v", err)
+	}
+
+	if err := c.Put("eu/realm", []byte("body"), CacheMeta{}); err != nil {
+		t.Fatalf("Put() unexpected error: %v", err)
+	}
+
+	if err := c.Invalidate("us/"); err != nil {
+		t.Fatalf("Invalidate() unexpected error: %v", err)
+	}
+
+	if _, _, err := c.Get("us/realm"); err != ErrCacheMiss {
+		t.Errorf("Get(\"us/realm\") err = %v, want ErrCacheMiss", err)
+	}
+
+	if _, _, err := c.Get("eu/realm"); err != nil {
+		t.Errorf("Get(\"eu/realm\") err = %v, want nil", err)
+	}
+}
+
+func Test_FileCache_roundTrip(t *testing.T) {
+	c := NewFileCache(t.TempDir())
+
+	if _, _, err := c.Get("us/realm"); err != ErrCacheMiss {
+		t.Errorf("Get() on empty cache err = %v, want ErrCacheMiss", err)
+	}
+
+	meta := CacheMeta{ETag: `"abc"`, LastModified: "Mon, 02 Jan 2006 15:04:05 GMT", Expiry: time.Now().Add(time.Hour)}
+	if err := c.Put("us/realm", []byte("body"), meta); err != nil {
+		t.Fatalf("Put() unexpected error: %v", err)
+	}
+
+	body, gotMeta, err := c.Get("us/realm")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+
+	if string(body) != "body" {
+		t.Errorf("Get() body = %q, want %q", body, "body")
+	}
+
+	if !gotMeta.fresh() {
+		t.Error("gotMeta.fresh() = false, want true within Expiry")
+	}
+
+	if gotMeta.ETag != meta.ETag || gotMeta.LastModified != meta.LastModified {
+		t.Errorf("gotMeta = %+v, want %+v", gotMeta, meta)
+	}
+}
+
+func Test_FileCache_invalidate(t *testing.T) {
+	c := NewFileCache(t.TempDir())
+
+	if err := c.Put("us/realm", []byte("body"), CacheMeta{}); err != nil {
+		t.Fatalf("Put() unexpected error: %v", err)
+	}
+
+	if err := c.Put("eu/realm", []byte("body"), CacheMeta{}); err != nil {
+		t.Fatalf("Put() unexpected error: %v", err)
+	}
+
+	if err := c.Invalidate("us/"); err != nil {
+		t.Fatalf("Invalidate() unexpected error: %v", err)
+	}
+
+	if _, _, err := c.Get("us/realm"); err != ErrCacheMiss {
+		t.Errorf("Get(\"us/realm\") err = %v, want ErrCacheMiss", err)
+	}
+
+	if _, _, err := c.Get("eu/realm"); err != nil {
+		t.Errorf("Get(\"eu/realm\") err = %v, want nil", err)
+	}
+}
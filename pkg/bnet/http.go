@@ -1,7 +1,7 @@
 package bnet
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/rs/zerolog"
@@ -10,34 +10,77 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 )
 
-// OAuthResponse is the response struct for a client_credentials request
-type oAuthResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
-}
-
 type BNet struct {
 	httpClient *http.Client
+	transport  *Transport
+	limiter    *limiter
+	metrics    Metrics
+	maxRetries int
+	cache      Cache
+	cacheTTL   time.Duration
 
 	oAuthUrl string
 	apiUrl   string
-
-	currentToken oAuthResponse
-
-	clientID     string
-	clientSecret string
+	locale   Locale
 
 	log *zerolog.Logger
 }
 
+// Locale returns the preferred locale this BNet was configured with.
+func (b *BNet) Locale() Locale {
+	return b.locale
+}
+
 type Options struct {
 	// Default: on
 	EnableLogging     bool
 	ProductionLogging bool
 	LogLevel          string
+
+	// HTTPClient, if set, is used instead of a freshly constructed
+	// http.Client. Its Transport becomes the delegate that BNet's auth
+	// Transport wraps, so callers can compose in their own middleware
+	// (tracing, custom TLS, etc).
+	HTTPClient *http.Client
+
+	// RoundTripper, if set, is used as the delegate transport instead of
+	// HTTPClient.Transport/http.DefaultTransport. Takes precedence over
+	// HTTPClient's transport when both are set.
+	RoundTripper http.RoundTripper
+
+	// TokenHandler, if set, replaces the default ClientCredentialsHandler,
+	// e.g. to use Authorization Code flow against user-scoped endpoints
+	// like the profile API.
+	TokenHandler TokenHandler
+
+	// RateLimits overrides the default per-second/per-hour request
+	// budget. Zero value uses defaultRateLimits.
+	RateLimits *RateLimits
+
+	// Metrics, if set, is notified of request latency/status, retries,
+	// and rate-limit waits. Defaults to NoopMetrics.
+	Metrics Metrics
+
+	// MaxRetries caps how many times a request is retried after a 429 or
+	// 5xx response. Defaults to defaultMaxRetries.
+	MaxRetries int
+
+	// Cache, if set, lets Get send conditional requests and serve 304s
+	// from a cached body instead of the network. Unset disables caching.
+	Cache Cache
+
+	// CacheTTL is how long a cached entry is trusted without revalidating
+	// against the server at all. Zero means every Get still makes a
+	// conditional request, it just may come back 304.
+	CacheTTL time.Duration
+
+	// Locale is the preferred locale for endpoints that return localized
+	// data, e.g. GetRealmList. Zero value defers to the region's
+	// DefaultLocale.
+	Locale Locale
 }
 
 const regionPlaceholder = "{region}"
@@ -53,6 +96,22 @@ func New(clientID, clientSecret, oAuthUrl, apiUrl string, options *Options) (*BN
 		return nil, fmt.Errorf("validate apiUrl: %+v", err)
 	}
 
+	return newBNet(clientID, clientSecret, oAuthUrl, apiUrl, options)
+}
+
+// NewForRegion builds a BNet bound to region, deriving its OAuth and API
+// hosts from Region.Host()/Region.OAuthHost() instead of requiring the
+// caller to string-format {region} placeholders by hand. This is the only
+// constructor that reaches CN correctly, since CN's OAuth host differs
+// from every other region's.
+func NewForRegion(clientID, clientSecret string, region Region, options *Options) (*BNet, error) {
+	oAuthUrl := fmt.Sprintf("https://%s", region.OAuthHost())
+	apiUrl := fmt.Sprintf("https://%s", region.Host())
+
+	return newBNet(clientID, clientSecret, oAuthUrl, apiUrl, options)
+}
+
+func newBNet(clientID, clientSecret, oAuthUrl, apiUrl string, options *Options) (*BNet, error) {
 	var logger zerolog.Logger
 	if !options.EnableLogging {
 		logger = zerolog.Nop()
@@ -64,16 +123,65 @@ func New(clientID, clientSecret, oAuthUrl, apiUrl string, options *Options) (*BN
 
 	logger = logger.With().Str("in", "auc-bnet").Logger()
 
+	httpClient := options.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	base := options.RoundTripper
+	if base == nil {
+		base = httpClient.Transport
+	}
+
+	handler := options.TokenHandler
+	if handler == nil {
+		handler = NewClientCredentialsHandler(
+			staticCredentialStore{clientID: clientID, clientSecret: clientSecret},
+			subRegion(oAuthUrl, "/oauth/token?grant_type=client_credentials", "us"),
+			nil,
+		)
+	}
+
+	transport := NewTransport(base, handler)
+
+	// Shallow-copy rather than mutate options.HTTPClient in place: a caller
+	// who passed in a client they use elsewhere (most dangerously
+	// http.DefaultClient) would otherwise have bnet's bearer token silently
+	// attached to every other request that client makes.
+	clientCopy := *httpClient
+	clientCopy.Transport = transport
+	httpClient = &clientCopy
+
+	limits := defaultRateLimits()
+	if options.RateLimits != nil {
+		limits = *options.RateLimits
+	}
+
+	metrics := options.Metrics
+	if metrics == nil {
+		metrics = NoopMetrics{}
+	}
+
+	maxRetries := options.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+
 	b := BNet{
-		httpClient:   &http.Client{},
-		clientID:     clientID,
-		clientSecret: clientSecret,
-		oAuthUrl:     oAuthUrl,
-		apiUrl:       apiUrl,
-		log:          &logger,
+		httpClient: httpClient,
+		transport:  transport,
+		limiter:    newLimiter(limits, metrics),
+		metrics:    metrics,
+		maxRetries: maxRetries,
+		cache:      options.Cache,
+		cacheTTL:   options.CacheTTL,
+		locale:     options.Locale,
+		oAuthUrl:   oAuthUrl,
+		apiUrl:     apiUrl,
+		log:        &logger,
 	}
 
-	if err := b.refreshOAuth(); err != nil {
+	if err := transport.primeToken(); err != nil {
 		return nil, err
 	}
 
@@ -102,95 +210,141 @@ func subRegion(base, path, region string) string {
 	return strings.Replace(fmt.Sprintf("%s%s%s", base, sep, endpoint), regionPlaceholder, region, -1)
 }
 
-func (b *BNet) refreshOAuth() error {
-	req, err := http.NewRequest("GET",
-		subRegion(b.oAuthUrl, "/oauth/token?grant_type=client_credentials", "us"),
-		nil)
-	if err != nil {
-		b.log.Err(err).Msg("Error creating bnet Request")
+// Get is a deprecated alias for GetCtx(context.Background(), ...). It will
+// be removed in a future release; callers should migrate to GetCtx so
+// cancellation and deadlines propagate all the way to the wire.
+func (b *BNet) Get(region, endpoint string) ([]byte, http.Header, error) {
+	return b.GetCtx(context.Background(), region, endpoint)
+}
 
-		return err
-	}
+// GetCtx wraps http.Get.
+// GetCtx also handles OAuth credentials, rate limiting, retries, and,
+// if a Cache is configured, conditional requests. ctx is honored by the
+// rate limiter wait, the retry backoff, and the underlying request.
+func (b *BNet) GetCtx(ctx context.Context, region, endpoint string) ([]byte, http.Header, error) {
+	url := subRegion(b.apiUrl, endpoint, region)
 
-	req.SetBasicAuth(b.clientID, b.clientSecret)
+	var cachedBody []byte
+	var cachedMeta CacheMeta
+	haveCached := false
 
-	// begin error hell
-	resp, err := b.httpClient.Do(req)
-	if err != nil {
-		b.log.Err(err).Msg("Error creating bnet Request")
+	if b.cache != nil {
+		if body, meta, err := b.cache.Get(cacheKey(region, endpoint)); err == nil {
+			cachedBody, cachedMeta, haveCached = body, meta, true
 
-		return err
+			if meta.fresh() {
+				return body, nil, nil
+			}
+		}
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	status, headers, body, err := b.getWithRetry(ctx, url, condHeaders(cachedMeta)...)
 	if err != nil {
-		b.log.Err(err).Msg("Error reading response body")
+		return nil, nil, err
+	}
 
-		return err
+	if status == http.StatusNotModified && haveCached {
+		return cachedBody, headers, nil
 	}
 
-	var respStruct oAuthResponse
+	if status > 0 && status != 200 {
+		b.log.Error().
+			Str("url", url).
+			Int("statusCode", status).
+			Str("body", string(body)).
+			Msg("BNet.Get failed")
+
+		return nil, nil, fmt.Errorf("response code %d", status)
+	}
 
-	if err = json.Unmarshal(body, &respStruct); err != nil {
-		b.log.Err(err).Str("body", string(body)).Msg("Error unmarshalling response")
+	if b.cache != nil {
+		meta := CacheMeta{
+			LastModified: headers.Get("Last-Modified"),
+			ETag:         headers.Get("ETag"),
+		}
+		if b.cacheTTL > 0 {
+			meta.Expiry = time.Now().Add(b.cacheTTL)
+		}
 
-		return err
+		if err := b.cache.Put(cacheKey(region, endpoint), body, meta); err != nil {
+			b.log.Err(err).Str("url", url).Msg("Error caching BNet.Get response")
+		}
 	}
 
-	if len(respStruct.AccessToken) == 0 {
-		b.log.Error().Msg("Could not retrieve access token")
+	return body, headers, err
+}
 
-		return ErrNoAccessToken
+// condHeaders builds the If-None-Match/If-Modified-Since header pairs for
+// a cached entry, so a revalidation request can come back 304.
+func condHeaders(meta CacheMeta) [][]string {
+	var h [][]string
+
+	if meta.ETag != "" {
+		h = append(h, []string{"If-None-Match", meta.ETag})
 	}
 
-	b.log.Info().Msg("Authenticated with Battle.net API")
+	if meta.LastModified != "" {
+		h = append(h, []string{"If-Modified-Since", meta.LastModified})
+	}
 
-	b.currentToken = respStruct
+	return h
+}
 
-	return nil
+// Concurrency reports how many requests a caller that fans out (e.g.
+// GetRealmList's connected-realm scrape) may safely have in flight at
+// once, sized off this BNet's per-second rate limit.
+func (b *BNet) Concurrency() int {
+	return b.limiter.concurrency()
 }
 
-// Get wraps http.Get.
-// Get also handles OAuth credentials and retries.
-func (b *BNet) Get(region, endpoint string) ([]byte, http.Header, error) {
-	url := subRegion(b.apiUrl, endpoint, region)
-	status, headers, body, err := b.get(url)
+// getWithRetry acquires a rate limiter slot and performs the request,
+// retrying with backoff on 429/5xx responses up to b.maxRetries times and
+// honoring a Retry-After header when the server sends one.
+func (b *BNet) getWithRetry(ctx context.Context, url string, headers ...[]string) (int, http.Header, []byte, error) {
+	var status int
+	var respHeaders http.Header
+	var body []byte
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		if err := b.limiter.wait(ctx); err != nil {
+			return -1, nil, nil, err
+		}
 
-	// retry once if a random 500 happens, sometimes it will resolve itself
-	if status == http.StatusInternalServerError {
-		status, _, body, err = b.get(url)
-	}
+		status, respHeaders, body, err = b.get(ctx, url, headers...)
+		if err != nil || !retryableStatus(status) || attempt == b.maxRetries {
+			return status, respHeaders, body, err
+		}
 
-	if status > 0 && status != 200 {
-		b.log.Error().
-			Str("url", url).
-			Int("statusCode", status).
-			Str("body", string(body)).
-			Msg("BNet.Get failed")
+		b.metrics.ObserveRetry(url)
 
-		return nil, nil, fmt.Errorf("response code %d", status)
+		wait := backoff(attempt, respHeaders.Get("Retry-After"))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return -1, nil, nil, ctx.Err()
+		}
 	}
-
-	return body, headers, err
 }
 
-func (b *BNet) get(url string, headers ...[]string) (int, http.Header, []byte, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (b *BNet) get(ctx context.Context, url string, headers ...[]string) (int, http.Header, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		b.log.Error().Msg("Could not retrieve access token")
 
 		return -1, nil, nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", b.currentToken.AccessToken))
-
 	for _, h := range headers {
 		if h != nil {
 			req.Header.Add(h[0], h[1])
 		}
 	}
 
+	start := time.Now()
+
+	// Authorization is attached by b.httpClient's Transport, which also
+	// refreshes the token ahead of expiry and retries once on 401/403.
 	response, err := b.httpClient.Do(req)
 	if err != nil {
 		b.log.Err(err).Msg("Error in http Do GET")
@@ -199,21 +353,13 @@ func (b *BNet) get(url string, headers ...[]string) (int, http.Header, []byte, e
 
 	defer response.Body.Close()
 
+	b.metrics.ObserveRequest(url, response.StatusCode, time.Since(start))
+
 	b.log.Debug().
 		Str("url", url).
 		Int("status", response.StatusCode).
 		Msg("Bnet API Request")
 
-	if response.StatusCode == 401 {
-		// OAuth is invalid, refresh
-		log.Info().Msg("Token expired, refreshing")
-		if err := b.refreshOAuth(); err != nil {
-			return -1, nil, nil, err
-		}
-
-		return b.get(url)
-	}
-
 	rawContents, err := ioutil.ReadAll(response.Body)
 	if err != nil {
 		b.log.Err(err).Msg("Error in http reading response body")
@@ -223,9 +369,16 @@ func (b *BNet) get(url string, headers ...[]string) (int, http.Header, []byte, e
 	return response.StatusCode, response.Header, rawContents, nil
 }
 
-// GetIfNotModified sets the If-Modified-Since header and returns true if a response was received, false otherwise
-// If a response is returned, return the value of the Last-Modified header
+// GetIfNotModified is a deprecated alias for
+// GetIfNotModifiedCtx(context.Background(), ...). It will be removed in a
+// future release; callers should migrate to GetIfNotModifiedCtx.
 func (b *BNet) GetIfNotModified(region, endpoint string, since string) (string, []byte, error) {
+	return b.GetIfNotModifiedCtx(context.Background(), region, endpoint, since)
+}
+
+// GetIfNotModifiedCtx sets the If-Modified-Since header and, on a 200, returns the value of the Last-Modified header
+// and the body. On a 304 Not Modified it returns an empty string and a nil body
+func (b *BNet) GetIfNotModifiedCtx(ctx context.Context, region, endpoint string, since string) (string, []byte, error) {
 	var h []string
 	if since != "" {
 		h = []string{"If-Modified-Since", since}
@@ -233,7 +386,7 @@ func (b *BNet) GetIfNotModified(region, endpoint string, since string) (string,
 
 	url := subRegion(b.apiUrl, endpoint, region)
 
-	status, headers, body, err := b.get(url, h)
+	status, headers, body, err := b.getWithRetry(ctx, url, h)
 	if err != nil || status == http.StatusNotModified {
 		return "", nil, err
 	}
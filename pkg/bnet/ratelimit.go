@@ -0,0 +1,166 @@
+package bnet
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Default rate limits, matching Battle.net's published per-second and
+// per-hour request quotas.
+const (
+	defaultPerSecond  = 100
+	defaultPerHour    = 36000
+	defaultMaxRetries = 3
+
+	// maxScrapeConcurrency caps the worker count BNet.Concurrency hands
+	// back to fan-out callers like GetRealmList, so a generous per-second
+	// limit doesn't translate into hundreds of goroutines queued on the
+	// same bucket.
+	maxScrapeConcurrency = 10
+)
+
+// RateLimits configures the token buckets BNet throttles outgoing requests
+// with. Battle.net enforces a per-second and a per-hour quota
+// independently, so both are tracked separately and a request waits on
+// whichever is tighter.
+type RateLimits struct {
+	PerSecond int
+	PerHour   int
+}
+
+func defaultRateLimits() RateLimits {
+	return RateLimits{
+		PerSecond: defaultPerSecond,
+		PerHour:   defaultPerHour,
+	}
+}
+
+// Metrics lets callers observe BNet's HTTP layer, the way Consul's HTTP
+// client emits per-endpoint RPC metrics. Implementations can wire these
+// into Prometheus/OpenTelemetry; embed NoopMetrics to implement only the
+// observers you care about.
+type Metrics interface {
+	// ObserveRequest is called once per completed HTTP round trip.
+	ObserveRequest(endpoint string, status int, latency time.Duration)
+	// ObserveRetry is called each time a request is retried after a 429
+	// or 5xx response.
+	ObserveRetry(endpoint string)
+	// ObserveRateLimitWait is called with the time a request spent
+	// blocked on the rate limiter before it was dispatched.
+	ObserveRateLimitWait(d time.Duration)
+}
+
+// NoopMetrics implements Metrics by discarding every observation. It is
+// the default when Options.Metrics is unset.
+type NoopMetrics struct{}
+
+func (NoopMetrics) ObserveRequest(string, int, time.Duration) {}
+func (NoopMetrics) ObserveRetry(string)                       {}
+func (NoopMetrics) ObserveRateLimitWait(time.Duration)        {}
+
+// limiter throttles requests against a per-second and a per-hour budget,
+// since Battle.net enforces both independently.
+type limiter struct {
+	perSecond *rate.Limiter
+	perHour   *rate.Limiter
+	metrics   Metrics
+}
+
+func newLimiter(limits RateLimits, metrics Metrics) *limiter {
+	if metrics == nil {
+		metrics = NoopMetrics{}
+	}
+
+	return &limiter{
+		perSecond: rate.NewLimiter(rate.Limit(limits.PerSecond), limits.PerSecond),
+		perHour:   rate.NewLimiter(rate.Limit(float64(limits.PerHour)/3600.0), limits.PerHour),
+		metrics:   metrics,
+	}
+}
+
+// wait blocks until both the per-second and per-hour budgets have a slot
+// free, or ctx is done.
+func (l *limiter) wait(ctx context.Context) error {
+	start := time.Now()
+
+	if err := l.perSecond.Wait(ctx); err != nil {
+		return err
+	}
+
+	if err := l.perHour.Wait(ctx); err != nil {
+		return err
+	}
+
+	if waited := time.Since(start); waited > 0 {
+		l.metrics.ObserveRateLimitWait(waited)
+	}
+
+	return nil
+}
+
+// concurrency sizes a worker count off the per-second limit, so fan-out
+// callers naturally cooperate with the same budget sequential calls use
+// instead of guessing a thread count.
+func (l *limiter) concurrency() int {
+	c := int(l.perSecond.Limit())
+	if c < 1 {
+		c = 1
+	}
+
+	if c > maxScrapeConcurrency {
+		c = maxScrapeConcurrency
+	}
+
+	return c
+}
+
+// retryableStatus reports whether status warrants a backoff-and-retry.
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// maxBackoffShift caps the exponent backoff shifts attempt by, so a large
+// Options.MaxRetries can't overflow time.Duration (int64 nanoseconds) and
+// send backoff's jitter call a negative bound.
+const maxBackoffShift = 20
+
+// backoff computes how long to wait before retry attempt (0-based),
+// honoring a Retry-After header when the server sent one and otherwise
+// falling back to exponential backoff with full jitter.
+func backoff(attempt int, retryAfter string) time.Duration {
+	if d, ok := parseRetryAfter(retryAfter); ok {
+		return d
+	}
+
+	shift := attempt
+	if shift > maxBackoffShift {
+		shift = maxBackoffShift
+	}
+
+	base := time.Duration(1<<uint(shift)) * 250 * time.Millisecond
+
+	return time.Duration(rand.Int63n(int64(base) + 1))
+}
+
+// parseRetryAfter parses a Retry-After header value, which may be either
+// a number of seconds or an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+
+	return 0, false
+}
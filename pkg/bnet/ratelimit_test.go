@@ -0,0 +1,75 @@
+package bnet
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{
+			name:   "empty",
+			value:  "",
+			wantOk: false,
+		},
+		{
+			name:   "seconds",
+			value:  "120",
+			want:   120 * time.Second,
+			wantOk: true,
+		},
+		{
+			name:   "zero seconds",
+			value:  "0",
+			want:   0,
+			wantOk: true,
+		},
+		{
+			name:   "not a number or date",
+			value:  "banana",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+			if ok != tt.wantOk {
+				t.Errorf("parseRetryAfter() ok = %v, want %v", ok, tt.wantOk)
+			}
+
+			if ok && got != tt.want {
+				t.Errorf("parseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_backoff_honorsRetryAfter(t *testing.T) {
+	got := backoff(0, "5")
+	if want := 5 * time.Second; got != want {
+		t.Errorf("backoff() = %v, want %v", got, want)
+	}
+}
+
+func Test_backoff_largeAttemptDoesNotPanic(t *testing.T) {
+	// Regression test: attempt values well beyond maxBackoffShift used to
+	// overflow time.Duration and make rand.Int63n panic on a negative bound.
+	for _, attempt := range []int{0, 1, 20, 40, 1000} {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("backoff(%d, \"\") panicked: %v", attempt, r)
+				}
+			}()
+
+			if d := backoff(attempt, ""); d < 0 {
+				t.Errorf("backoff(%d, \"\") = %v, want non-negative", attempt, d)
+			}
+		}()
+	}
+}
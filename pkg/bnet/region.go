@@ -0,0 +1,100 @@
+package bnet
+
+import "fmt"
+
+// Region identifies a Battle.net API region. Each has its own API host
+// and set of supported locales; CN additionally uses a distinct OAuth
+// host from the rest of the world.
+type Region string
+
+const (
+	RegionUS Region = "us"
+	RegionEU Region = "eu"
+	RegionKR Region = "kr"
+	RegionTW Region = "tw"
+	RegionCN Region = "cn"
+)
+
+// Locale is a Battle.net locale code, e.g. "en_US".
+type Locale string
+
+const (
+	LocaleEnUS Locale = "en_US"
+	LocaleEsMX Locale = "es_MX"
+	LocalePtBR Locale = "pt_BR"
+	LocaleEnGB Locale = "en_GB"
+	LocaleEsES Locale = "es_ES"
+	LocaleFrFR Locale = "fr_FR"
+	LocaleRuRU Locale = "ru_RU"
+	LocaleDeDE Locale = "de_DE"
+	LocalePtPT Locale = "pt_PT"
+	LocaleItIT Locale = "it_IT"
+	LocaleKoKR Locale = "ko_KR"
+	LocaleZhTW Locale = "zh_TW"
+	LocaleZhCN Locale = "zh_CN"
+)
+
+// IsValid reports whether r is one of the regions Battle.net serves.
+func (r Region) IsValid() bool {
+	switch r {
+	case RegionUS, RegionEU, RegionKR, RegionTW, RegionCN:
+		return true
+	default:
+		return false
+	}
+}
+
+// Host returns the region's API host, e.g. "us.api.blizzard.com". CN is
+// served from a distinct domain entirely.
+func (r Region) Host() string {
+	if r == RegionCN {
+		return "gateway.battlenet.com.cn"
+	}
+
+	return fmt.Sprintf("%s.api.blizzard.com", r)
+}
+
+// OAuthHost returns the region's OAuth host. Every region but CN shares
+// the same global host.
+func (r Region) OAuthHost() string {
+	if r == RegionCN {
+		return "www.battlenet.com.cn"
+	}
+
+	return "oauth.battle.net"
+}
+
+// DefaultLocale returns the locale GetRealmListCtx uses when the caller
+// doesn't ask for a specific one.
+func (r Region) DefaultLocale() Locale {
+	switch r {
+	case RegionEU:
+		return LocaleEnGB
+	case RegionKR:
+		return LocaleKoKR
+	case RegionTW:
+		return LocaleZhTW
+	case RegionCN:
+		return LocaleZhCN
+	default:
+		return LocaleEnUS
+	}
+}
+
+// Locales returns every locale the region serves.
+func (r Region) Locales() []Locale {
+	switch r {
+	case RegionUS:
+		return []Locale{LocaleEnUS, LocaleEsMX, LocalePtBR}
+	case RegionEU:
+		return []Locale{LocaleEnGB, LocaleEsES, LocaleFrFR, LocaleRuRU, LocaleDeDE, LocalePtPT, LocaleItIT}
+	case RegionKR:
+		return []Locale{LocaleKoKR}
+	case RegionTW:
+		return []Locale{LocaleZhTW}
+	case RegionCN:
+		return []Locale{LocaleZhCN}
+	default:
+		return nil
+	}
+}
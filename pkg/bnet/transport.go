@@ -0,0 +1,308 @@
+package bnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRefreshSkew is how far ahead of a token's expiry Transport will
+// proactively fetch a replacement, so a request is never the thing that
+// discovers the token has expired.
+const defaultRefreshSkew = 60 * time.Second
+
+// Challenge is a parsed WWW-Authenticate header, e.g.
+//
+//	Bearer realm="https://oauth.battle.net/token",service="oauth.battle.net"
+//
+// This mirrors the bearer challenge format used by OAuth2-protected
+// registries/APIs, so a TokenHandler can decide how to authenticate
+// without Transport needing to know the grant type.
+type Challenge struct {
+	Scheme  string
+	Realm   string
+	Service string
+	Scope   string
+}
+
+// parseChallenge parses a single WWW-Authenticate header value.
+func parseChallenge(header string) (Challenge, bool) {
+	scheme, params := header, ""
+	if i := strings.IndexByte(header, ' '); i >= 0 {
+		scheme, params = header[:i], header[i+1:]
+	}
+
+	c := Challenge{Scheme: scheme}
+	for _, part := range strings.Split(params, ",") {
+		part = strings.TrimSpace(part)
+		eq := strings.IndexByte(part, '=')
+		if eq < 0 {
+			continue
+		}
+
+		key := strings.TrimSpace(part[:eq])
+		val := strings.Trim(strings.TrimSpace(part[eq+1:]), `"`)
+
+		switch key {
+		case "realm":
+			c.Realm = val
+		case "service":
+			c.Service = val
+		case "scope":
+			c.Scope = val
+		}
+	}
+
+	return c, c.Scheme != ""
+}
+
+// challengeFromResponse looks for a WWW-Authenticate header on resp and
+// parses it, returning ok=false if the response did not carry one.
+func challengeFromResponse(resp *http.Response) (Challenge, bool) {
+	header := resp.Header.Get("WWW-Authenticate")
+	if header == "" {
+		return Challenge{}, false
+	}
+
+	return parseChallenge(header)
+}
+
+// Token is an OAuth access token together with the time it expires, as
+// returned by a client_credentials or authorization_code grant.
+type Token struct {
+	AccessToken string
+	TokenType   string
+	ExpiresAt   time.Time
+}
+
+// expiringWithin reports whether t has no access token yet, or will expire
+// within skew of now.
+func (t Token) expiringWithin(skew time.Duration) bool {
+	if t.AccessToken == "" {
+		return true
+	}
+
+	return time.Now().Add(skew).After(t.ExpiresAt)
+}
+
+// TokenHandler exchanges a challenge for a usable access token. The
+// bundled ClientCredentialsHandler mirrors BNet's historical behavior;
+// callers that need user-scoped endpoints (e.g. the profile API) can
+// supply their own handler implementing Authorization Code instead.
+type TokenHandler interface {
+	Token(challenge Challenge) (Token, error)
+}
+
+// CredentialStore supplies the credentials a TokenHandler authenticates
+// with. It is deliberately minimal so handlers for other grant types can
+// reuse it without depending on ClientCredentialsHandler.
+type CredentialStore interface {
+	ClientID() string
+	ClientSecret() string
+}
+
+// staticCredentialStore is the CredentialStore used when BNet.New is
+// given a client ID/secret pair directly, rather than a caller-supplied
+// CredentialStore.
+type staticCredentialStore struct {
+	clientID     string
+	clientSecret string
+}
+
+func (s staticCredentialStore) ClientID() string     { return s.clientID }
+func (s staticCredentialStore) ClientSecret() string { return s.clientSecret }
+
+// ClientCredentialsHandler implements the OAuth2 client_credentials grant,
+// the flow Battle.net's data APIs expect. It is the default TokenHandler
+// used by New.
+type ClientCredentialsHandler struct {
+	creds      CredentialStore
+	tokenURL   string
+	httpClient *http.Client
+}
+
+// NewClientCredentialsHandler builds a ClientCredentialsHandler that
+// requests tokens from tokenURL using creds. httpClient is used to make
+// the token request; if nil, http.DefaultClient is used.
+func NewClientCredentialsHandler(creds CredentialStore, tokenURL string, httpClient *http.Client) *ClientCredentialsHandler {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &ClientCredentialsHandler{
+		creds:      creds,
+		tokenURL:   tokenURL,
+		httpClient: httpClient,
+	}
+}
+
+// oAuthTokenResponse is the response body of a client_credentials request.
+type oAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Token requests a new access token via client_credentials. The challenge
+// is ignored: Battle.net's token endpoint is fixed at construction time,
+// unlike flows where the realm varies per-resource.
+func (h *ClientCredentialsHandler) Token(_ Challenge) (Token, error) {
+	req, err := http.NewRequest("GET", h.tokenURL, nil)
+	if err != nil {
+		return Token{}, fmt.Errorf("build token request: %w", err)
+	}
+
+	req.SetBasicAuth(h.creds.ClientID(), h.creds.ClientSecret())
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("do token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Token{}, fmt.Errorf("read token response: %w", err)
+	}
+
+	var tr oAuthTokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return Token{}, fmt.Errorf("unmarshal token response: %w", err)
+	}
+
+	if tr.AccessToken == "" {
+		return Token{}, ErrNoAccessToken
+	}
+
+	return Token{
+		AccessToken: tr.AccessToken,
+		TokenType:   tr.TokenType,
+		ExpiresAt:   time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// Transport is an http.RoundTripper that attaches a bearer token to every
+// request, refreshing it ahead of expiry via Handler rather than waiting
+// for a 401. If a request still comes back 401/403 (e.g. the token was
+// revoked early, or this is the first request and the challenge reveals a
+// realm/scope the Handler didn't know about), the WWW-Authenticate
+// challenge is parsed and the request retried once with a fresh token.
+type Transport struct {
+	// Base is the delegate transport requests are ultimately sent on.
+	Base http.RoundTripper
+
+	// Handler mints tokens for this transport.
+	Handler TokenHandler
+
+	// RefreshSkew is how far ahead of expiry a cached token is considered
+	// stale. Defaults to defaultRefreshSkew when zero.
+	RefreshSkew time.Duration
+
+	mu    sync.Mutex
+	token Token
+}
+
+// NewTransport builds a Transport that authenticates requests using
+// handler, sending them on base. base defaults to http.DefaultTransport
+// when nil.
+func NewTransport(base http.RoundTripper, handler TokenHandler) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{
+		Base:        base,
+		Handler:     handler,
+		RefreshSkew: defaultRefreshSkew,
+	}
+}
+
+// primeToken fetches an initial token so the first real request doesn't
+// pay for it. Kept separate from RoundTrip so New can surface auth
+// failures immediately, as it always has.
+func (t *Transport) primeToken() error {
+	_, err := t.currentToken()
+
+	return err
+}
+
+func (t *Transport) skew() time.Duration {
+	if t.RefreshSkew <= 0 {
+		return defaultRefreshSkew
+	}
+
+	return t.RefreshSkew
+}
+
+// currentToken returns the cached token, refreshing it first if it is
+// unset or within skew of expiring.
+func (t *Transport) currentToken() (Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token.expiringWithin(t.skew()) {
+		token, err := t.Handler.Token(Challenge{})
+		if err != nil {
+			return Token{}, err
+		}
+
+		t.token = token
+	}
+
+	return t.token, nil
+}
+
+// refreshWith replaces the cached token using the given challenge,
+// e.g. after a 401 revealed realm/scope the Handler needs.
+func (t *Transport) refreshWith(challenge Challenge) (Token, error) {
+	token, err := t.Handler.Token(challenge)
+	if err != nil {
+		return Token{}, err
+	}
+
+	t.mu.Lock()
+	t.token = token
+	t.mu.Unlock()
+
+	return token, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.currentToken()
+	if err != nil {
+		return nil, fmt.Errorf("acquire token: %w", err)
+	}
+
+	resp, err := t.Base.RoundTrip(authorize(req, token))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden {
+		return resp, nil
+	}
+
+	challenge, _ := challengeFromResponse(resp)
+	resp.Body.Close()
+
+	token, err = t.refreshWith(challenge)
+	if err != nil {
+		return nil, fmt.Errorf("refresh token after %d: %w", resp.StatusCode, err)
+	}
+
+	return t.Base.RoundTrip(authorize(req, token))
+}
+
+// authorize returns a clone of req (via Request.Clone) with the bearer token attached,
+// per http.RoundTripper's contract that RoundTrip must not modify req.
+func authorize(req *http.Request, token Token) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
+
+	return clone
+}
@@ -0,0 +1,96 @@
+package bnet
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseChallenge(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   Challenge
+		wantOk bool
+	}{
+		{
+			name:   "realm and service",
+			header: `Bearer realm="https://oauth.battle.net/token",service="oauth.battle.net"`,
+			want: Challenge{
+				Scheme:  "Bearer",
+				Realm:   "https://oauth.battle.net/token",
+				Service: "oauth.battle.net",
+			},
+			wantOk: true,
+		},
+		{
+			name:   "realm, service, and scope",
+			header: `Bearer realm="https://oauth.battle.net/token",service="oauth.battle.net",scope="wow.auctions"`,
+			want: Challenge{
+				Scheme:  "Bearer",
+				Realm:   "https://oauth.battle.net/token",
+				Service: "oauth.battle.net",
+				Scope:   "wow.auctions",
+			},
+			wantOk: true,
+		},
+		{
+			name:   "scheme only",
+			header: "Bearer",
+			want:   Challenge{Scheme: "Bearer"},
+			wantOk: true,
+		},
+		{
+			name:   "empty header",
+			header: "",
+			want:   Challenge{},
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseChallenge(tt.header)
+			if ok != tt.wantOk {
+				t.Errorf("parseChallenge() ok = %v, want %v", ok, tt.wantOk)
+			}
+
+			if got != tt.want {
+				t.Errorf("parseChallenge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Token_expiringWithin(t *testing.T) {
+	tests := []struct {
+		name  string
+		token Token
+		skew  time.Duration
+		want  bool
+	}{
+		{
+			name:  "no access token",
+			token: Token{},
+			skew:  time.Minute,
+			want:  true,
+		},
+		{
+			name:  "expires within skew",
+			token: Token{AccessToken: "tok", ExpiresAt: time.Now().Add(30 * time.Second)},
+			skew:  time.Minute,
+			want:  true,
+		},
+		{
+			name:  "expires well after skew",
+			token: Token{AccessToken: "tok", ExpiresAt: time.Now().Add(time.Hour)},
+			skew:  time.Minute,
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.token.expiringWithin(tt.skew); got != tt.want {
+				t.Errorf("expiringWithin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}